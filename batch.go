@@ -0,0 +1,272 @@
+/*
+ * Copyright 2014-2023 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois information query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultBatchWorkers is used when BatchOptions.Workers is not set
+	defaultBatchWorkers = 10
+	// defaultBatchRetries is used when BatchOptions.Retries is not set
+	defaultBatchRetries = 2
+	// defaultBatchInitialBackoff is the first retry delay
+	defaultBatchInitialBackoff = 500 * time.Millisecond
+	// defaultBatchMaxBackoff caps the exponential backoff delay
+	defaultBatchMaxBackoff = 10 * time.Second
+	// defaultServerQPS is used for a server with no explicit rate limit
+	defaultServerQPS = 1.0
+)
+
+// BatchOptions configures Client.WhoisBatch.
+type BatchOptions struct {
+	// Workers is the number of domains queried concurrently. Defaults
+	// to defaultBatchWorkers.
+	Workers int
+	// Retries is how many additional attempts are made after a failed
+	// query, with exponential backoff between attempts. Defaults to
+	// defaultBatchRetries.
+	Retries int
+	// InitialBackoff is the delay before the first retry. Defaults to
+	// defaultBatchInitialBackoff.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponential backoff delay. Defaults to
+	// defaultBatchMaxBackoff.
+	MaxBackoff time.Duration
+	// ServerQPS limits how many queries per second are sent to a given
+	// whois server, keyed by server hostname. Servers not present here
+	// use defaultServerQPS.
+	ServerQPS map[string]float64
+}
+
+// Result is one domain's outcome from Client.WhoisBatch.
+type Result struct {
+	Domain   string
+	Text     string
+	Elapsed  time.Duration
+	Attempts int
+	Err      error
+}
+
+// WhoisBatch fans out whois queries for domains over a bounded pool of
+// workers and caps each whois server's queries per second with a
+// token bucket - looping over Client.Whois instead tends to open a
+// connection per domain at once, which is how issue #27's fd and
+// goroutine exhaustion started. Results stream back as they complete;
+// the channel closes once every domain has been queried or ctx is done.
+func (c *Client) WhoisBatch(ctx context.Context, domains []string, opts BatchOptions) <-chan Result {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultBatchWorkers
+	}
+
+	retries := opts.Retries
+	if retries <= 0 {
+		retries = defaultBatchRetries
+	}
+
+	initialBackoff := opts.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = defaultBatchInitialBackoff
+	}
+
+	maxBackoff := opts.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultBatchMaxBackoff
+	}
+
+	limiters := newServerLimiters(opts.ServerQPS)
+
+	in := make(chan string)
+	out := make(chan Result)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for domain := range in {
+				result := c.whoisWithRetry(ctx, domain, retries, initialBackoff, maxBackoff, limiters)
+				select {
+				case out <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(in)
+		for _, domain := range domains {
+			select {
+			case in <- domain:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// whoisWithRetry runs a single domain's query, retrying with
+// exponential backoff and jitter on failure.
+func (c *Client) whoisWithRetry(ctx context.Context, domain string, retries int, initialBackoff, maxBackoff time.Duration, limiters *serverLimiters) Result {
+	start := time.Now()
+	backoff := initialBackoff
+
+	var lastErr error
+	for attempt := 1; attempt <= retries+1; attempt++ {
+		limiters.wait(ctx, c.serverForDomain(domain))
+
+		// Bypass the negative-cache on any retry, or it would just hand
+		// back the previous attempt's cached error without re-dialing.
+		text, err := c.whois(domain, attempt > 1)
+		if err == nil {
+			return Result{Domain: domain, Text: text, Elapsed: time.Since(start), Attempts: attempt}
+		}
+
+		lastErr = err
+		if attempt > retries {
+			break
+		}
+
+		select {
+		case <-time.After(jitter(backoff)):
+		case <-ctx.Done():
+			return Result{Domain: domain, Elapsed: time.Since(start), Attempts: attempt, Err: ctx.Err()}
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	return Result{Domain: domain, Elapsed: time.Since(start), Attempts: retries + 1, Err: lastErr}
+}
+
+// serverForDomain resolves the server a domain would be queried
+// against, for rate-limiting purposes, without performing the query.
+func (c *Client) serverForDomain(domain string) string {
+	ext := getExtension(domain)
+	if v, ok := c.serverMap.GetWhoisServer(ext); ok {
+		return v
+	}
+	return defaultWhoisServer
+}
+
+// jitter returns d plus up to 20% random jitter, so retrying workers
+// don't all wake up and hit the same server at once.
+func jitter(d time.Duration) time.Duration {
+	return d + time.Duration(rand.Int63n(int64(d)/5+1))
+}
+
+// serverLimiters holds one token bucket per whois server.
+type serverLimiters struct {
+	mu      sync.Mutex
+	qps     map[string]float64
+	buckets map[string]*tokenBucket
+}
+
+func newServerLimiters(qps map[string]float64) *serverLimiters {
+	return &serverLimiters{
+		qps:     qps,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// wait blocks until a token is available for server, or ctx is done.
+func (l *serverLimiters) wait(ctx context.Context, server string) {
+	l.mu.Lock()
+	b, ok := l.buckets[server]
+	if !ok {
+		rate := l.qps[server]
+		if rate <= 0 {
+			rate = defaultServerQPS
+		}
+		b = newTokenBucket(rate)
+		l.buckets[server] = b
+	}
+	l.mu.Unlock()
+
+	b.take(ctx)
+}
+
+// tokenBucket is a simple token-bucket rate limiter refilling at a
+// fixed number of tokens per second, capped at one second's worth
+// (or at one token, for rates below one per second).
+type tokenBucket struct {
+	mu         sync.Mutex
+	ratePerSec float64
+	tokens     float64
+	last       time.Time
+}
+
+func newTokenBucket(ratePerSec float64) *tokenBucket {
+	return &tokenBucket{
+		ratePerSec: ratePerSec,
+		tokens:     ratePerSec,
+		last:       time.Now(),
+	}
+}
+
+// take blocks until a token is available or ctx is done.
+func (b *tokenBucket) take(ctx context.Context) {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		// A rate below one per second still has to be able to reach a
+		// whole token, or take would never return.
+		burst := b.ratePerSec
+		if burst < 1 {
+			burst = 1
+		}
+		b.tokens += now.Sub(b.last).Seconds() * b.ratePerSec
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+		b.last = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return
+		}
+		wait := time.Duration((1 - b.tokens) / b.ratePerSec * float64(time.Second))
+		b.mu.Unlock()
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2014-2023 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois information query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// redirectDialer sends every dial to a fixed local address, standing
+// in for a real whois server so tests don't touch the network.
+type redirectDialer struct {
+	addr string
+}
+
+func (d redirectDialer) Dial(network, _ string) (net.Conn, error) {
+	return net.Dial(network, d.addr)
+}
+
+// startFlakyServer listens on 127.0.0.1, RST-closing (not just
+// dropping) the first failUntil connections it sees so the client
+// observes a real connection error, then replying with resp after
+// that. It returns the listener address and a counter of connections
+// handled so far.
+func startFlakyServer(t *testing.T, failUntil int32, resp string) (addr string, attempts *int32) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	var n int32
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				count := atomic.AddInt32(&n, 1)
+				if count <= failUntil {
+					if tc, ok := conn.(*net.TCPConn); ok {
+						_ = tc.SetLinger(0)
+					}
+					conn.Close()
+					return
+				}
+
+				defer conn.Close()
+				_, _ = conn.Write([]byte(resp))
+			}(conn)
+		}
+	}()
+
+	return ln.Addr().String(), &n
+}
+
+// This exercises a domain lookup through NewClient, which resolves the
+// "com" extension through the package-wide server map - it relies on
+// that map defaulting to the compiled-in fallback snapshot rather than
+// being nil, since nothing here calls InitWhois.
+func TestWhoisBatchRetryBypassesNegativeCache(t *testing.T) {
+	addr, attempts := startFlakyServer(t, 1, "domain found\n")
+
+	c := NewClient().
+		SetDialer(redirectDialer{addr: addr}).
+		SetCache(NewLRUCache(16)).
+		SetCacheNegativeTTL(time.Minute). // long enough that a real cache hit would mask the bug
+		SetTimeout(2 * time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	results := c.WhoisBatch(ctx, []string{"example.com"}, BatchOptions{
+		Workers:        1,
+		Retries:        2,
+		InitialBackoff: 10 * time.Millisecond,
+	})
+
+	var got []Result
+	for r := range results {
+		got = append(got, r)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(got))
+	}
+	if got[0].Err != nil {
+		t.Fatalf("expected eventual success after retry, got err: %v", got[0].Err)
+	}
+	if !strings.Contains(got[0].Text, "domain found") {
+		t.Fatalf("unexpected result text: %q", got[0].Text)
+	}
+	if atomic.LoadInt32(attempts) < 2 {
+		t.Fatalf("expected the server to be re-dialed on retry (negative cache should be bypassed), got %d attempts", *attempts)
+	}
+}
+
+func TestWhoisBatchDoesNotLeakWhenConsumerStopsReading(t *testing.T) {
+	addr, _ := startFlakyServer(t, 0, "ok\n")
+
+	c := NewClient().SetDialer(redirectDialer{addr: addr}).SetTimeout(time.Second)
+
+	domains := make([]string, 20)
+	for i := range domains {
+		domains[i] = fmt.Sprintf("example%d.com", i)
+	}
+
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := c.WhoisBatch(ctx, domains, BatchOptions{Workers: 4, Retries: 0})
+
+	// Read exactly one result, then give up on the batch entirely - the
+	// scenario WhoisBatch's doc comment promises to handle cleanly.
+	<-results
+	cancel()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if runtime.NumGoroutine() <= before+2 { // small slack for runtime bookkeeping
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("goroutines did not settle after context cancel: before=%d after=%d", before, runtime.NumGoroutine())
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright 2014-2023 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois information query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultCacheMaxEntries is used by NewLRUCache when maxEntries is
+	// zero or negative
+	defaultCacheMaxEntries = 1024
+	// defaultCacheTTL is how long a successful lookup is cached
+	defaultCacheTTL = time.Hour
+	// defaultCacheNegativeTTL is how long a failed lookup is cached,
+	// so a misbehaving or down registry isn't hammered on every retry
+	defaultCacheNegativeTTL = time.Minute
+)
+
+// Cache is the interface a whois result cache must implement. Get
+// reports whether key is present and not expired; Set stores value
+// under key with the given ttl; Purge drops everything.
+type Cache interface {
+	Get(key string) (string, bool)
+	Set(key, value string, ttl time.Duration)
+	Purge()
+}
+
+// SetCache sets the cache used for query results and TLD server
+// resolution. Pass nil to disable caching.
+func (c *Client) SetCache(cache Cache) *Client {
+	c.cache = cache
+	return c
+}
+
+// SetCacheTTL sets the TTL applied to successfully cached results.
+func (c *Client) SetCacheTTL(ttl time.Duration) *Client {
+	c.cacheTTL = ttl
+	return c
+}
+
+// SetCacheNegativeTTL sets the TTL applied to cached errors.
+func (c *Client) SetCacheNegativeTTL(ttl time.Duration) *Client {
+	c.cacheNegativeTTL = ttl
+	return c
+}
+
+// lruCache is the default in-memory Cache, an LRU keyed by string
+// with a per-entry expiry, modeled on AdGuardHome's WHOIS cache.
+type lruCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	value   string
+	expires time.Time
+}
+
+// NewLRUCache returns an in-memory Cache that evicts the least
+// recently used entry once it holds more than maxEntries items.
+func NewLRUCache(maxEntries int) Cache {
+	if maxEntries <= 0 {
+		maxEntries = defaultCacheMaxEntries
+	}
+
+	return &lruCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get returns the cached value for key, evicting it first if expired.
+func (c *lruCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+
+	entry := el.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		c.removeElement(el)
+		return "", false
+	}
+
+	c.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+// Set stores value under key, evicting the oldest entry if the cache
+// is full.
+func (c *lruCache) Set(key, value string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		el.Value.(*lruEntry).value = value
+		el.Value.(*lruEntry).expires = time.Now().Add(ttl)
+		return
+	}
+
+	el := c.ll.PushFront(&lruEntry{key: key, value: value, expires: time.Now().Add(ttl)})
+	c.items[key] = el
+
+	if c.ll.Len() > c.maxEntries {
+		c.removeOldest()
+	}
+}
+
+// Purge drops all cached entries.
+func (c *lruCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+func (c *lruCache) removeOldest() {
+	el := c.ll.Back()
+	if el != nil {
+		c.removeElement(el)
+	}
+}
+
+func (c *lruCache) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*lruEntry).key)
+}
+
+// cacheKey builds the cache key for a raw query to server/port for domain.
+func cacheKey(domain, server, port string) string {
+	return domain + "|" + server + "|" + port
+}
+
+// cacheErrPrefix flags a cached value as an error rather than a real
+// response body - Cache only stores strings, so errors piggyback on
+// the same storage with this prefix.
+const cacheErrPrefix = "\x00err\x00"
+
+// encodeCacheErr wraps err's message so it can be round-tripped
+// through the Cache's string-only storage.
+func encodeCacheErr(err error) string {
+	return cacheErrPrefix + err.Error()
+}
+
+// cacheErrMessage reports whether value is a cached error and, if so,
+// returns the original error message.
+func cacheErrMessage(value string) (string, bool) {
+	if len(value) >= len(cacheErrPrefix) && value[:len(cacheErrPrefix)] == cacheErrPrefix {
+		return value[len(cacheErrPrefix):], true
+	}
+	return "", false
+}
@@ -0,0 +1,229 @@
+/*
+ * Copyright 2014-2023 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois information query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+)
+
+// RespType classifies a whois response so callers don't have to
+// guess "not found" from free-form text on every registry.
+type RespType string
+
+const (
+	// RespFound means the query matched a registered domain/IP
+	RespFound RespType = "found"
+	// RespNotFound means the registry reported no match
+	RespNotFound RespType = "not_found"
+	// RespParseError means the raw response could not be parsed
+	RespParseError RespType = "parse_error"
+	// RespError means the query itself failed (network, server, etc.)
+	RespError RespType = "error"
+	// RespTimeout means the query exceeded its deadline
+	RespTimeout RespType = "timeout"
+)
+
+// Info is the typed, parsed result of a whois query, kept alongside
+// the raw text so callers that need the original response still have it.
+type Info struct {
+	Raw      string
+	RespType RespType
+
+	// Domain fields
+	Registrar   string
+	Registrant  string
+	NameServers []string
+	CreatedDate string
+	UpdatedDate string
+	ExpiryDate  string
+	Statuses    []string
+	DNSSEC      string
+
+	// IP/ASN fields
+	NetRange string
+	CIDR     string
+	ASN      string
+	Country  string
+	RIR      string
+}
+
+// Parser turns a raw whois response for a given domain/IP into an Info.
+// Implementations are expected to be stateless and safe for concurrent use.
+type Parser interface {
+	Parse(query, raw string) (*Info, error)
+}
+
+var (
+	parsersMu sync.RWMutex
+	parsers   = map[string]Parser{}
+)
+
+// RegisterParser wires p up as the Parser for a TLD or RIR key (e.g.
+// "com", "co.uk", "arin"); an existing entry for the same key is
+// replaced.
+func RegisterParser(tld string, p Parser) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	parsers[strings.ToLower(tld)] = p
+}
+
+// lookupParser returns the parser registered for tld, if any.
+func lookupParser(tld string) (Parser, bool) {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	p, ok := parsers[strings.ToLower(tld)]
+	return p, ok
+}
+
+// WhoisParsed does the whois query like Whois, but also parses the
+// response into a typed Info using the parser registered for the
+// domain's TLD, falling back to genericParser when none is registered.
+func (c *Client) WhoisParsed(domain string, servers ...string) (*Info, error) {
+	raw, err := c.Whois(domain, servers...)
+	if err != nil {
+		if isTimeoutErr(err) {
+			return &Info{RespType: RespTimeout}, err
+		}
+		return &Info{RespType: RespError}, err
+	}
+
+	key := getExtension(domain)
+	if IsASN(domain) || net.ParseIP(domain) != nil {
+		// IP/ASN queries aren't served by a TLD, so route them to a
+		// RIR parser by name instead of by getExtension's result.
+		key = "arin"
+	}
+
+	p, ok := lookupParser(key)
+	if !ok {
+		p = genericParser{}
+	}
+
+	info, err := p.Parse(domain, raw)
+	if err != nil {
+		return &Info{Raw: raw, RespType: RespParseError}, err
+	}
+
+	info.Raw = raw
+	if info.RespType == "" {
+		info.RespType = RespFound
+	}
+
+	return info, nil
+}
+
+// genericParser is the fallback Parser used when no TLD/RIR specific
+// parser has been registered. It extracts the handful of fields that
+// are spelled the same way across most thin-registry WHOIS responses.
+type genericParser struct{}
+
+// notFoundMarkers are substrings registries commonly use to report
+// that a domain has no registration.
+var notFoundMarkers = []string{
+	"no match",
+	"not found",
+	"no entries found",
+	"no data found",
+	"status: free",
+	"domain not found",
+}
+
+func (genericParser) Parse(_, raw string) (*Info, error) {
+	lower := strings.ToLower(raw)
+	for _, marker := range notFoundMarkers {
+		if strings.Contains(lower, marker) {
+			return &Info{RespType: RespNotFound}, nil
+		}
+	}
+
+	info := &Info{RespType: RespFound}
+	for _, line := range strings.Split(raw, "\n") {
+		key, value, ok := splitWhoisLine(line)
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "registrar":
+			info.Registrar = value
+		case "registrant", "registrant name", "registrant organization":
+			if info.Registrant == "" {
+				info.Registrant = value
+			}
+		case "name server", "nserver":
+			info.NameServers = append(info.NameServers, value)
+		case "creation date", "created", "created on":
+			info.CreatedDate = value
+		case "updated date", "last updated", "modified":
+			info.UpdatedDate = value
+		case "registry expiry date", "expiration date", "expires on", "expiry date":
+			info.ExpiryDate = value
+		case "domain status", "status":
+			info.Statuses = append(info.Statuses, value)
+		case "dnssec":
+			info.DNSSEC = value
+		case "netrange":
+			info.NetRange = value
+		case "cidr":
+			info.CIDR = value
+		case "originas", "origin", "asnumber":
+			info.ASN = value
+		case "country":
+			info.Country = value
+		}
+	}
+
+	return info, nil
+}
+
+// splitWhoisLine splits a "Key: value" whois line, trimming whitespace
+// on both sides. It returns ok=false for blank lines or lines without
+// a colon, which whois responses use freely for comments and banners.
+func splitWhoisLine(line string) (key, value string, ok bool) {
+	idx := strings.Index(line, ":")
+	if idx == -1 {
+		return "", "", false
+	}
+
+	key = strings.TrimSpace(line[:idx])
+	value = strings.TrimSpace(line[idx+1:])
+	if key == "" || value == "" {
+		return "", "", false
+	}
+
+	return key, value, true
+}
+
+// isTimeoutErr reports whether err came from a deadline being hit
+// rather than, say, a connection refusal or a bad response - either
+// the context deadline used to bound the whole query, or a deadline
+// set directly on the socket (conn.SetReadDeadline/SetWriteDeadline).
+func isTimeoutErr(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2014-2023 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois information query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"strings"
+)
+
+func init() {
+	RegisterParser("arin", arinParser{})
+}
+
+// arinParser parses ARIN's IP/ASN whois format. It's close to the
+// generic "Key: value" shape but under different key names
+// (NetRange/CIDR/OrgName/...), and is the only RIR-specific parser
+// registered so far - RIPE, APNIC, LACNIC and AFRINIC use their own
+// layouts and fall back to genericParser's best-effort field scan
+// until one is added here.
+type arinParser struct{}
+
+func (arinParser) Parse(_, raw string) (*Info, error) {
+	lower := strings.ToLower(raw)
+	for _, marker := range notFoundMarkers {
+		if strings.Contains(lower, marker) {
+			return &Info{RespType: RespNotFound}, nil
+		}
+	}
+
+	info := &Info{RespType: RespFound, RIR: "ARIN"}
+	for _, line := range strings.Split(raw, "\n") {
+		key, value, ok := splitWhoisLine(line)
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "netrange":
+			info.NetRange = value
+		case "cidr":
+			info.CIDR = value
+		case "originas":
+			info.ASN = value
+		case "country":
+			info.Country = value
+		case "orgname", "customer":
+			info.Registrant = value
+		}
+	}
+
+	return info, nil
+}
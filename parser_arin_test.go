@@ -0,0 +1,65 @@
+/*
+ * Copyright 2014-2023 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois information query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"testing"
+)
+
+const sampleARINWhois = `NetRange:       8.8.8.0 - 8.8.8.255
+CIDR:           8.8.8.0/24
+OriginAS:       AS15169
+NetName:        LVLT-GOGL-8-8-8
+OrgName:        Google LLC
+Country:        US
+`
+
+func TestArinParserParse(t *testing.T) {
+	info, err := arinParser{}.Parse("8.8.8.8", sampleARINWhois)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if info.RIR != "ARIN" {
+		t.Errorf("RIR = %q, want %q", info.RIR, "ARIN")
+	}
+	if info.NetRange != "8.8.8.0 - 8.8.8.255" {
+		t.Errorf("NetRange = %q, want %q", info.NetRange, "8.8.8.0 - 8.8.8.255")
+	}
+	if info.CIDR != "8.8.8.0/24" {
+		t.Errorf("CIDR = %q, want %q", info.CIDR, "8.8.8.0/24")
+	}
+	if info.ASN != "AS15169" {
+		t.Errorf("ASN = %q, want %q", info.ASN, "AS15169")
+	}
+	if info.Country != "US" {
+		t.Errorf("Country = %q, want %q", info.Country, "US")
+	}
+}
+
+func TestArinParserParseNotFound(t *testing.T) {
+	info, err := arinParser{}.Parse("203.0.113.1", "No match found for 203.0.113.1.\n")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if info.RespType != RespNotFound {
+		t.Errorf("RespType = %q, want %q", info.RespType, RespNotFound)
+	}
+}
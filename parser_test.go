@@ -0,0 +1,56 @@
+/*
+ * Copyright 2014-2023 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois information query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestIsTimeoutErr(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"context deadline exceeded", fmt.Errorf("whois: connect failed: %w", context.DeadlineExceeded), true},
+		{"net timeout error", &net.OpError{Op: "read", Err: timeoutError{}}, true},
+		{"plain error", errors.New("connection refused"), false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := isTimeoutErr(c.err); got != c.want {
+				t.Errorf("isTimeoutErr(%v) = %v, want %v", c.err, got, c.want)
+			}
+		})
+	}
+}
+
+// timeoutError is a minimal net.Error whose Timeout() is true, for
+// exercising isTimeoutErr's net.Error branch without a real socket.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
@@ -0,0 +1,416 @@
+/*
+ * Copyright 2014-2023 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois information query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rdapBootstrapTTL is how long an IANA RDAP bootstrap file is cached
+// before it's refetched.
+const rdapBootstrapTTL = 24 * time.Hour
+
+// rdapBootstrapURLs maps each bootstrap registry IANA publishes to its
+// data.iana.org URL.
+var rdapBootstrapURLs = map[string]string{
+	"dns":  "https://data.iana.org/rdap/dns.json",
+	"ipv4": "https://data.iana.org/rdap/ipv4.json",
+	"ipv6": "https://data.iana.org/rdap/ipv6.json",
+	"asn":  "https://data.iana.org/rdap/asn.json",
+}
+
+// RDAPResponse is the decoded JSON body of an RDAP lookup. RDAP
+// responses vary by server, so the rarely-needed fields are left in
+// Raw for callers that need more than the common ones below.
+type RDAPResponse struct {
+	ObjectClassName string                 `json:"objectClassName"`
+	Handle          string                 `json:"handle"`
+	LDHName         string                 `json:"ldhName"`
+	Status          []string               `json:"status"`
+	Nameservers     []RDAPNameserver       `json:"nameservers"`
+	Events          []RDAPEvent            `json:"events"`
+	Entities        []RDAPEntity           `json:"entities"`
+	Raw             map[string]interface{} `json:"-"`
+}
+
+// RDAPNameserver is one entry of RDAPResponse.Nameservers.
+type RDAPNameserver struct {
+	LDHName string `json:"ldhName"`
+}
+
+// RDAPEvent is one entry of RDAPResponse.Events, e.g. an action of
+// "registration" or "expiration" with its date.
+type RDAPEvent struct {
+	Action string `json:"eventAction"`
+	Date   string `json:"eventDate"`
+}
+
+// RDAPEntity is one entry of RDAPResponse.Entities, e.g. the
+// registrar or registrant.
+type RDAPEntity struct {
+	Handle string   `json:"handle"`
+	Roles  []string `json:"roles"`
+}
+
+// SetPreferRDAP sets whether Client.Lookup tries RDAP before falling
+// back to legacy WHOIS (true, the default) or goes straight to WHOIS
+// (false).
+func (c *Client) SetPreferRDAP(prefer bool) *Client {
+	c.preferRDAP = prefer
+	return c
+}
+
+// RDAP looks up query via RDAP. query may be a domain, an IPv4/IPv6
+// address, or an ASN (e.g. "AS15169"); each is routed to its own IANA
+// bootstrap registry (dns, ipv4, ipv6, asn respectively). It returns
+// an error if nothing is registered for query's TLD/range, so callers
+// that want a WHOIS fallback should use Lookup instead.
+func (c *Client) RDAP(query string) (*RDAPResponse, error) {
+	query = strings.Trim(strings.TrimSpace(query), ".")
+	if query == "" {
+		return nil, ErrDomainEmpty
+	}
+
+	switch {
+	case IsASN(query):
+		asn, err := parseASN(query)
+		if err != nil {
+			return nil, err
+		}
+
+		base, err := c.rdapBaseForASN(asn)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.rdapFetch(fmt.Sprintf("%s/autnum/%d", strings.TrimRight(base, "/"), asn))
+
+	case net.ParseIP(query) != nil:
+		base, err := c.rdapBaseForIP(net.ParseIP(query))
+		if err != nil {
+			return nil, err
+		}
+
+		return c.rdapFetch(fmt.Sprintf("%s/ip/%s", strings.TrimRight(base, "/"), query))
+
+	default:
+		base, err := c.rdapBase("dns", getExtension(query))
+		if err != nil {
+			return nil, err
+		}
+
+		return c.rdapFetch(fmt.Sprintf("%s/domain/%s", strings.TrimRight(base, "/"), query))
+	}
+}
+
+// Lookup resolves query via RDAP when c.preferRDAP is set (the
+// default), falling back to legacy WHOIS when RDAP is unsupported for
+// query or the server returns 404. With preferRDAP disabled it goes
+// straight to WHOIS. On success exactly one of the RDAP response and
+// the WHOIS text is set.
+func (c *Client) Lookup(query string) (*RDAPResponse, string, error) {
+	if c.preferRDAP {
+		resp, err := c.RDAP(query)
+		if err == nil {
+			return resp, "", nil
+		}
+		if !isRDAPUnsupported(err) {
+			return nil, "", err
+		}
+	}
+
+	text, err := c.Whois(query)
+	return nil, text, err
+}
+
+// parseASN extracts the numeric value out of an ASN query like
+// "AS15169" or "15169".
+func parseASN(query string) (uint32, error) {
+	n := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(query)), asnPrefix)
+	v, err := strconv.ParseUint(n, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("whois: invalid asn %q: %w", query, err)
+	}
+	return uint32(v), nil
+}
+
+// rdapFetch performs the HTTP GET and decodes the RDAP JSON body.
+func (c *Client) rdapFetch(url string) (*RDAPResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("whois: rdap request to %s failed: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errRDAPNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("whois: rdap request to %s failed: status %s", url, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("whois: rdap response from %s could not be read: %w", url, err)
+	}
+
+	var out RDAPResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("whois: rdap response from %s is not valid JSON: %w", url, err)
+	}
+
+	// Decoded a second time into a generic map so Raw carries whatever
+	// the server sent beyond the common fields above - RDAP responses
+	// vary a lot server to server, and re-parsing here is cheaper than
+	// adding a field for every vendor extension.
+	if err := json.Unmarshal(body, &out.Raw); err != nil {
+		return nil, fmt.Errorf("whois: rdap response from %s is not valid JSON: %w", url, err)
+	}
+
+	return &out, nil
+}
+
+// errRDAPNotFound and errRDAPUnsupported are the two ways RDAP lookup
+// can fail "normally" (as opposed to a network or parse error), which
+// Lookup treats as a signal to fall back to legacy WHOIS.
+var (
+	errRDAPNotFound    = fmt.Errorf("whois: rdap: no match")
+	errRDAPUnsupported = fmt.Errorf("whois: rdap: unsupported tld")
+)
+
+// isRDAPUnsupported reports whether err is one of the RDAP failure
+// modes that Lookup should fall back to WHOIS for.
+func isRDAPUnsupported(err error) bool {
+	return err == errRDAPNotFound || err == errRDAPUnsupported
+}
+
+// rdapBootstrapEntry is one row of an IANA RDAP bootstrap file: a set
+// of keys (TLDs, CIDR blocks, or "start-end" ASN ranges) sharing the
+// same candidate RDAP base URLs.
+type rdapBootstrapEntry struct {
+	Keys []string
+	URLs []string
+}
+
+// rdapBootstrap caches one IANA RDAP bootstrap registry (dns, ipv4,
+// ipv6 or asn), refreshing it once it's older than rdapBootstrapTTL.
+type rdapBootstrap struct {
+	mu      sync.Mutex
+	fetched time.Time
+	entries []rdapBootstrapEntry
+}
+
+var (
+	rdapBootstraps   = map[string]*rdapBootstrap{}
+	rdapBootstrapsMu sync.Mutex
+)
+
+// rdapEntries returns the cached bootstrap entries for registry,
+// refreshing them from IANA if the cached copy has expired. A stale
+// cached copy is preferred over a hard failure if the refresh errors.
+func (c *Client) rdapEntries(registry string) ([]rdapBootstrapEntry, error) {
+	rdapBootstrapsMu.Lock()
+	b, ok := rdapBootstraps[registry]
+	if !ok {
+		b = &rdapBootstrap{}
+		rdapBootstraps[registry] = b
+	}
+	rdapBootstrapsMu.Unlock()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if time.Since(b.fetched) > rdapBootstrapTTL {
+		entries, err := fetchRDAPBootstrap(registry)
+		switch {
+		case err == nil:
+			b.entries = entries
+			b.fetched = time.Now()
+		case b.entries == nil:
+			return nil, err
+		}
+	}
+
+	return b.entries, nil
+}
+
+// rdapBase returns the first RDAP base URL registered for key (an
+// exact match, case-insensitively) in the given registry.
+func (c *Client) rdapBase(registry, key string) (string, error) {
+	entries, err := c.rdapEntries(registry)
+	if err != nil {
+		return "", err
+	}
+
+	key = strings.ToLower(key)
+	for _, e := range entries {
+		for _, k := range e.Keys {
+			if strings.ToLower(k) == key && len(e.URLs) > 0 {
+				return e.URLs[0], nil
+			}
+		}
+	}
+
+	return "", errRDAPUnsupported
+}
+
+// rdapBaseForASN finds the "asn" bootstrap entry whose "start-end"
+// range contains asn.
+func (c *Client) rdapBaseForASN(asn uint32) (string, error) {
+	entries, err := c.rdapEntries("asn")
+	if err != nil {
+		return "", err
+	}
+
+	for _, e := range entries {
+		if len(e.URLs) == 0 {
+			continue
+		}
+		for _, key := range e.Keys {
+			if lo, hi, ok := parseASNRange(key); ok && asn >= lo && asn <= hi {
+				return e.URLs[0], nil
+			}
+		}
+	}
+
+	return "", errRDAPUnsupported
+}
+
+// parseASNRange parses a bootstrap ASN key of the form "lo-hi" (IANA
+// publishes single ASNs as "n-n").
+func parseASNRange(key string) (lo, hi uint32, ok bool) {
+	parts := strings.SplitN(key, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	loVal, errLo := strconv.ParseUint(parts[0], 10, 32)
+	hiVal, errHi := strconv.ParseUint(parts[1], 10, 32)
+	if errLo != nil || errHi != nil {
+		return 0, 0, false
+	}
+
+	return uint32(loVal), uint32(hiVal), true
+}
+
+// rdapBaseForIP finds the most specific (longest-prefix-match) CIDR
+// bootstrap entry covering ip, picking "ipv4" or "ipv6" as the
+// registry depending on the address family.
+func (c *Client) rdapBaseForIP(ip net.IP) (string, error) {
+	registry := "ipv4"
+	if ip.To4() == nil {
+		registry = "ipv6"
+	}
+
+	entries, err := c.rdapEntries(registry)
+	if err != nil {
+		return "", err
+	}
+
+	var bestURL string
+	bestPrefixLen := -1
+	for _, e := range entries {
+		if len(e.URLs) == 0 {
+			continue
+		}
+		for _, key := range e.Keys {
+			_, ipNet, err := net.ParseCIDR(key)
+			if err != nil || !ipNet.Contains(ip) {
+				continue
+			}
+			if prefixLen, _ := ipNet.Mask.Size(); prefixLen > bestPrefixLen {
+				bestPrefixLen = prefixLen
+				bestURL = e.URLs[0]
+			}
+		}
+	}
+
+	if bestURL == "" {
+		return "", errRDAPUnsupported
+	}
+
+	return bestURL, nil
+}
+
+// rdapBootstrapFile mirrors the IANA RDAP bootstrap JSON shape:
+// {"services": [[["com","net"], ["https://rdap.verisign.com/..."]], ...]}
+type rdapBootstrapFile struct {
+	Services [][][]string `json:"services"`
+}
+
+// fetchRDAPBootstrap downloads one IANA RDAP bootstrap file (dns,
+// ipv4, ipv6 or asn) and returns its entries in file order.
+func fetchRDAPBootstrap(registry string) ([]rdapBootstrapEntry, error) {
+	url, ok := rdapBootstrapURLs[registry]
+	if !ok {
+		return nil, fmt.Errorf("whois: unknown rdap bootstrap registry %q", registry)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultElapsedTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("whois: fetch rdap bootstrap (%s) failed: %w", registry, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("whois: fetch rdap bootstrap (%s) failed: status %s", registry, resp.Status)
+	}
+
+	var file rdapBootstrapFile
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return nil, fmt.Errorf("whois: parse rdap bootstrap (%s) failed: %w", registry, err)
+	}
+
+	entries := make([]rdapBootstrapEntry, 0, len(file.Services))
+	for _, service := range file.Services {
+		if len(service) != 2 {
+			continue
+		}
+		entries = append(entries, rdapBootstrapEntry{Keys: service[0], URLs: service[1]})
+	}
+
+	return entries, nil
+}
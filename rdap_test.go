@@ -0,0 +1,114 @@
+/*
+ * Copyright 2014-2023 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois information query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestParseASN(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    uint32
+		wantErr bool
+	}{
+		{"AS15169", 15169, false},
+		{"as15169", 15169, false},
+		{"15169", 15169, false},
+		{"ASnotanumber", 0, true},
+	}
+
+	for _, c := range cases {
+		got, err := parseASN(c.in)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("parseASN(%q): expected error, got %d", c.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseASN(%q): unexpected error: %v", c.in, err)
+		}
+		if got != c.want {
+			t.Errorf("parseASN(%q) = %d, want %d", c.in, got, c.want)
+		}
+	}
+}
+
+func TestRDAPBaseForASN(t *testing.T) {
+	seedRDAPBootstrap(t, "asn", []rdapBootstrapEntry{
+		{Keys: []string{"1-1876"}, URLs: []string{"https://rdap.example/asn-low/"}},
+		{Keys: []string{"15169-15169"}, URLs: []string{"https://rdap.example/google-asn/"}},
+	})
+
+	c := NewClient()
+
+	base, err := c.rdapBaseForASN(15169)
+	if err != nil {
+		t.Fatalf("rdapBaseForASN(15169) failed: %v", err)
+	}
+	if base != "https://rdap.example/google-asn/" {
+		t.Errorf("rdapBaseForASN(15169) = %q, want the matching range's URL", base)
+	}
+
+	if _, err := c.rdapBaseForASN(999999); !isRDAPUnsupported(err) {
+		t.Errorf("rdapBaseForASN(999999) = (_, %v), want errRDAPUnsupported", err)
+	}
+}
+
+func TestRDAPBaseForIPPrefersMostSpecificCIDR(t *testing.T) {
+	seedRDAPBootstrap(t, "ipv4", []rdapBootstrapEntry{
+		{Keys: []string{"8.0.0.0/8"}, URLs: []string{"https://rdap.example/broad/"}},
+		{Keys: []string{"8.8.8.0/24"}, URLs: []string{"https://rdap.example/specific/"}},
+	})
+
+	c := NewClient()
+
+	base, err := c.rdapBaseForIP(net.ParseIP("8.8.8.8"))
+	if err != nil {
+		t.Fatalf("rdapBaseForIP(8.8.8.8) failed: %v", err)
+	}
+	if base != "https://rdap.example/specific/" {
+		t.Errorf("rdapBaseForIP(8.8.8.8) = %q, want the more specific /24 entry's URL", base)
+	}
+
+	if _, err := c.rdapBaseForIP(net.ParseIP("9.9.9.9")); !isRDAPUnsupported(err) {
+		t.Errorf("rdapBaseForIP(9.9.9.9) = (_, %v), want errRDAPUnsupported", err)
+	}
+}
+
+// seedRDAPBootstrap injects a fresh, already-fetched bootstrap cache
+// entry for registry, so tests can exercise the lookup/match logic
+// without reaching the network.
+func seedRDAPBootstrap(t *testing.T, registry string, entries []rdapBootstrapEntry) {
+	t.Helper()
+
+	rdapBootstrapsMu.Lock()
+	rdapBootstraps[registry] = &rdapBootstrap{entries: entries, fetched: time.Now()}
+	rdapBootstrapsMu.Unlock()
+
+	t.Cleanup(func() {
+		rdapBootstrapsMu.Lock()
+		delete(rdapBootstraps, registry)
+		rdapBootstrapsMu.Unlock()
+	})
+}
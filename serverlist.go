@@ -0,0 +1,150 @@
+/*
+ * Copyright 2014-2023 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois information query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// whoisServerListURL is the community-maintained source of truth for
+// TLD whois servers, used by LoadFromWhoisServerList.
+const whoisServerListURL = "http://whois-server-list.github.io/whois-server-list/3.0/whois-server-list.xml"
+
+// whoisServerList mirrors the subset of whois-server-list.xml we care
+// about. Each <record> has a <domain name="..."/> and a sibling
+// <host value="..."/>, not a nested whoisServer element:
+//
+//	<WhoisServerList>
+//	  <whoisServer>
+//	    <record>
+//	      <domain name="com"/>
+//	      <host value="whois.verisign-grs.com"/>
+//	    </record>
+//	  </whoisServer>
+//	</WhoisServerList>
+type whoisServerList struct {
+	XMLName xml.Name          `xml:"WhoisServerList"`
+	Records []whoisListRecord `xml:"whoisServer>record"`
+}
+
+type whoisListRecord struct {
+	Domain whoisListDomain `xml:"domain"`
+	Host   whoisListHost   `xml:"host"`
+}
+
+type whoisListDomain struct {
+	Name string `xml:"name,attr"`
+}
+
+type whoisListHost struct {
+	Value string `xml:"value,attr"`
+}
+
+// LoadFromWhoisServerList fetches url (pass "" to use the canonical
+// whois-server-list.xml) and merges its TLD-to-server entries into m.
+func (m *serverMap) LoadFromWhoisServerList(ctx context.Context, url string) error {
+	if url == "" {
+		url = whoisServerListURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("whois: fetch whois-server-list failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("whois: fetch whois-server-list failed: status %s", resp.Status)
+	}
+
+	return m.LoadFromReader(resp.Body)
+}
+
+// LoadFromReader parses whois-server-list XML from r and merges its
+// entries into m. Useful for a local file, an embedded copy, or
+// anything else that isn't the canonical URL.
+func (m *serverMap) LoadFromReader(r io.Reader) error {
+	entries, err := ParseWhoisServerList(r)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for ext, host := range entries {
+		m.servers[ext] = host
+	}
+
+	return nil
+}
+
+// ParseWhoisServerList parses whois-server-list XML from r into a
+// TLD-to-server map, without touching any serverMap. It's exported so
+// the snapshot generator (and callers building their own tooling) can
+// reuse the same parsing logic as LoadFromReader.
+func ParseWhoisServerList(r io.Reader) (map[string]string, error) {
+	var list whoisServerList
+	if err := xml.NewDecoder(r).Decode(&list); err != nil {
+		return nil, fmt.Errorf("whois: parse whois-server-list failed: %w", err)
+	}
+
+	entries := make(map[string]string, len(list.Records))
+	for _, rec := range list.Records {
+		host := strings.TrimSpace(rec.Host.Value)
+		ext := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(rec.Domain.Name), "."))
+		if host == "" || ext == "" {
+			continue
+		}
+		entries[ext] = host
+	}
+
+	return entries, nil
+}
+
+// RefreshWhoisServerList reloads the whois-server-list from url (pass
+// "" for the canonical URL) every interval, until ctx is done. A
+// failed refresh is swallowed rather than propagated - callers that
+// care about refresh errors should drive LoadFromWhoisServerList on
+// their own schedule instead.
+func (m *serverMap) RefreshWhoisServerList(ctx context.Context, url string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = m.LoadFromWhoisServerList(ctx, url)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
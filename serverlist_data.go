@@ -0,0 +1,48 @@
+/*
+ * Copyright 2014-2023 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois information query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+// fallbackServers is a compiled-in snapshot of whois-server-list.xml,
+// so ServerMap works offline without a user-supplied config. It is
+// deliberately small; run `go generate ./...` to regenerate it from
+// the latest whois-server-list.xml (see serverlist_generate.go).
+//
+//go:generate go run serverlist_generate.go
+var fallbackServers = map[string]string{
+	"com":    "whois.verisign-grs.com",
+	"net":    "whois.verisign-grs.com",
+	"org":    "whois.pir.org",
+	"info":   "whois.afilias.net",
+	"io":     "whois.nic.io",
+	"dev":    "whois.nic.google",
+	"app":    "whois.nic.google",
+	"co":     "whois.nic.co",
+	"me":     "whois.nic.me",
+	"biz":    "whois.nic.biz",
+	"us":     "whois.nic.us",
+	"uk":     "whois.nic.uk",
+	"de":     "whois.denic.de",
+	"fr":     "whois.nic.fr",
+	"jp":     "whois.jprs.jp",
+	"cn":     "whois.cnnic.cn",
+	"ai":     "whois.nic.ai",
+	"xyz":    "whois.nic.xyz",
+	"online": "whois.nic.online",
+}
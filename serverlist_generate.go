@@ -0,0 +1,118 @@
+//go:build ignore
+
+/*
+ * Copyright 2014-2023 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois information query
+ * https://www.likexian.com/
+ */
+
+// Command serverlist_generate regenerates serverlist_data.go from the
+// live whois-server-list.xml. It's excluded from normal builds via the
+// "ignore" build tag and run through `go generate ./...`.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/darkqiank/whois-core"
+)
+
+const outputPath = "serverlist_data.go"
+
+var tmpl = template.Must(template.New("data").Parse(`/*
+ * Copyright 2014-2023 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois information query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+// fallbackServers is a compiled-in snapshot of whois-server-list.xml,
+// so ServerMap works offline without a user-supplied config. Run
+// ` + "`go generate ./...`" + ` to regenerate it from the latest
+// whois-server-list.xml (see serverlist_generate.go).
+//
+//go:generate go run serverlist_generate.go
+var fallbackServers = map[string]string{
+{{- range .}}
+	"{{.Ext}}": "{{.Server}}",
+{{- end}}
+}
+`))
+
+type entry struct {
+	Ext    string
+	Server string
+}
+
+func main() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://whois-server-list.github.io/whois-server-list/3.0/whois-server-list.xml", nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	m, err := whois.ParseWhoisServerList(resp.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	entries := make([]entry, 0, len(m))
+	for ext, server := range m {
+		entries = append(entries, entry{Ext: ext, Server: server})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Ext < entries[j].Ext })
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := tmpl.Execute(f, entries); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("wrote %d entries to %s\n", len(entries), outputPath)
+}
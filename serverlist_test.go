@@ -0,0 +1,94 @@
+/*
+ * Copyright 2014-2023 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois information query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"strings"
+	"testing"
+)
+
+// sampleWhoisServerListXML is a trimmed real-world snippet of
+// whois-server-list.xml (https://github.com/whois-server-list/whois-server-list),
+// kept small but schema-accurate: each <record> has a <domain> and a
+// sibling <host>, not a <host> nested inside a <whoisServer> element.
+const sampleWhoisServerListXML = `<?xml version="1.0" encoding="UTF-8"?>
+<WhoisServerList xmlns="https://www.nirsoft.net/whois-server-list/">
+  <whoisServer>
+    <record>
+      <domain name="com"/>
+      <host value="whois.verisign-grs.com"/>
+    </record>
+    <record>
+      <domain name="net"/>
+      <host value="whois.verisign-grs.com"/>
+    </record>
+    <record>
+      <domain name="io"/>
+      <host value="whois.nic.io"/>
+    </record>
+    <record>
+      <domain name="example-no-host"/>
+    </record>
+  </whoisServer>
+</WhoisServerList>
+`
+
+func TestParseWhoisServerList(t *testing.T) {
+	entries, err := ParseWhoisServerList(strings.NewReader(sampleWhoisServerListXML))
+	if err != nil {
+		t.Fatalf("ParseWhoisServerList failed: %v", err)
+	}
+
+	want := map[string]string{
+		"com": "whois.verisign-grs.com",
+		"net": "whois.verisign-grs.com",
+		"io":  "whois.nic.io",
+	}
+
+	for ext, server := range want {
+		if got := entries[ext]; got != server {
+			t.Errorf("entries[%q] = %q, want %q", ext, got, server)
+		}
+	}
+
+	if _, ok := entries["example-no-host"]; ok {
+		t.Errorf("expected record with no <host> to be skipped")
+	}
+
+	if len(entries) != len(want) {
+		t.Errorf("len(entries) = %d, want %d (entries: %v)", len(entries), len(want), entries)
+	}
+}
+
+func TestServerMapLoadFromReaderMerges(t *testing.T) {
+	m := NewServerMap()
+	// "io" is already in the fallback seed, so overwrite it first: the
+	// check below then only holds if LoadFromReader merged the list in.
+	m.SetWhoisServer("io", "whois.stale.example")
+
+	if err := m.LoadFromReader(strings.NewReader(sampleWhoisServerListXML)); err != nil {
+		t.Fatalf("LoadFromReader failed: %v", err)
+	}
+
+	server, ok := m.GetWhoisServer("io")
+	if !ok || server != "whois.nic.io" {
+		t.Errorf("GetWhoisServer(%q) = (%q, %v), want (%q, true)", "io", server, ok, "whois.nic.io")
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2014-2023 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois information query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+)
+
+// serverMap holds the TLD-to-whois-server lookup table, plus a small
+// table of servers that should be rewritten before dialing (some
+// registries publish a referral hostname that itself needs redirecting).
+type serverMap struct {
+	mu       sync.RWMutex
+	servers  map[string]string
+	rewrites map[string]string
+}
+
+// serverMapConfig is the on-disk shape accepted by LoadFromFile.
+type serverMapConfig struct {
+	Servers  map[string]string `json:"servers"`
+	Rewrites map[string]string `json:"rewrites"`
+}
+
+// NewServerMap returns a serverMap seeded with the compiled-in
+// fallback snapshot, so lookups work before any config is loaded.
+func NewServerMap() *serverMap {
+	m := &serverMap{
+		servers:  make(map[string]string, len(fallbackServers)),
+		rewrites: make(map[string]string),
+	}
+
+	for ext, server := range fallbackServers {
+		m.servers[ext] = server
+	}
+
+	return m
+}
+
+// GetWhoisServer returns the whois server registered for ext (a TLD
+// such as "com" or "co.uk"), if any.
+func (m *serverMap) GetWhoisServer(ext string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	v, ok := m.servers[strings.ToLower(ext)]
+	return v, ok
+}
+
+// SetWhoisServer registers server as the whois server for ext.
+func (m *serverMap) SetWhoisServer(ext, server string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.servers[strings.ToLower(ext)] = server
+}
+
+// GetRewriteServer returns the server that host should be rewritten
+// to before dialing, if one is registered.
+func (m *serverMap) GetRewriteServer(host string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	v, ok := m.rewrites[strings.ToLower(host)]
+	return v, ok
+}
+
+// LoadFromFile loads a JSON config of the form
+// {"servers": {"com": "whois.verisign-grs.com"}, "rewrites": {...}}
+// and merges it into the map, overriding any existing entries.
+func (m *serverMap) LoadFromFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var cfg serverMapConfig
+	if err := json.NewDecoder(f).Decode(&cfg); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for ext, server := range cfg.Servers {
+		m.servers[strings.ToLower(ext)] = server
+	}
+	for host, server := range cfg.Rewrites {
+		m.rewrites[strings.ToLower(host)] = server
+	}
+
+	return nil
+}
@@ -33,9 +33,24 @@ import (
 
 var (
 	serverMapInstance *serverMap
+	serverMapOnce     sync.Once
 	onceWhois         sync.Once
 )
 
+// defaultServerMap returns the package-wide serverMap, seeding it with
+// the compiled-in fallback snapshot on first use so lookups work even
+// if InitWhois is never called. InitWhois merges its config into this
+// same instance rather than replacing it, so clients built before
+// InitWhois runs still see the loaded config.
+func defaultServerMap() *serverMap {
+	serverMapOnce.Do(func() {
+		if serverMapInstance == nil {
+			serverMapInstance = NewServerMap()
+		}
+	})
+	return serverMapInstance
+}
+
 const (
 	// defaultWhoisServer is iana whois server
 	defaultWhoisServer = "whois.iana.org"
@@ -59,6 +74,13 @@ type Client struct {
 	disableReferral bool
 
 	serverMap *serverMap
+
+	dialContextFunc DialContextFunc
+	preferRDAP      bool
+
+	cache            Cache
+	cacheTTL         time.Duration
+	cacheNegativeTTL time.Duration
 }
 
 // Version returns package version
@@ -87,8 +109,11 @@ func NewClient() *Client {
 		dialer: &net.Dialer{
 			Timeout: defaultTimeout,
 		},
-		timeout:   defaultElapsedTimeout,
-		serverMap: serverMapInstance,
+		timeout:          defaultElapsedTimeout,
+		serverMap:        defaultServerMap(),
+		preferRDAP:       true,
+		cacheTTL:         defaultCacheTTL,
+		cacheNegativeTTL: defaultCacheNegativeTTL,
 	}
 }
 
@@ -118,6 +143,15 @@ func (c *Client) SetDisableReferral(disabled bool) *Client {
 
 // Whois do the whois query and returns whois information
 func (c *Client) Whois(domain string, servers ...string) (result string, err error) {
+	return c.whois(domain, false, servers...)
+}
+
+// whois is the implementation behind Whois. bypassCachedErr, when
+// true, treats a negatively-cached entry as a cache miss and re-dials
+// instead of replaying the cached error - used by WhoisBatch's retry
+// path so a retry isn't just handed back the same cached failure it's
+// trying to retry past.
+func (c *Client) whois(domain string, bypassCachedErr bool, servers ...string) (result string, err error) {
 	start := time.Now()
 	defer func() {
 		result = strings.TrimSpace(result)
@@ -141,7 +175,7 @@ func (c *Client) Whois(domain string, servers ...string) (result string, err err
 	}
 
 	if !strings.Contains(domain, ".") && !strings.Contains(domain, ":") && !isASN {
-		return c.rawQuery(domain, defaultWhoisServer, defaultWhoisPort)
+		return c.rawQuery(domain, defaultWhoisServer, defaultWhoisPort, bypassCachedErr)
 	}
 
 	var server, port string
@@ -155,7 +189,7 @@ func (c *Client) Whois(domain string, servers ...string) (result string, err err
 			server = v
 			port = defaultWhoisPort
 		} else {
-			result, err := c.rawQuery(ext, defaultWhoisServer, defaultWhoisPort)
+			result, err := c.rawQuery(ext, defaultWhoisServer, defaultWhoisPort, bypassCachedErr)
 			if err != nil {
 				return "", fmt.Errorf("whois: query for whois server failed: %w", err)
 			}
@@ -168,7 +202,7 @@ func (c *Client) Whois(domain string, servers ...string) (result string, err err
 		}
 	}
 
-	result, err = c.rawQuery(domain, server, port)
+	result, err = c.rawQuery(domain, server, port, bypassCachedErr)
 	if err != nil {
 		return
 	}
@@ -182,7 +216,7 @@ func (c *Client) Whois(domain string, servers ...string) (result string, err err
 		return
 	}
 
-	data, err := c.rawQuery(domain, refServer, refPort)
+	data, err := c.rawQuery(domain, refServer, refPort, bypassCachedErr)
 	if err == nil {
 		result += data
 	}
@@ -190,8 +224,32 @@ func (c *Client) Whois(domain string, servers ...string) (result string, err err
 	return
 }
 
-// rawQuery do raw query to the server
-func (c *Client) rawQuery(domain, server, port string) (string, error) {
+// rawQuery do raw query to the server. bypassCachedErr skips a
+// negatively-cached entry (treating it as a miss) so a fresh dial is
+// made instead of replaying the same cached error; successful entries
+// are always honored.
+func (c *Client) rawQuery(domain, server, port string, bypassCachedErr bool) (result string, err error) {
+	if c.cache != nil {
+		key := cacheKey(domain, server, port)
+		if cached, ok := c.cache.Get(key); ok {
+			msg, isErr := cacheErrMessage(cached)
+			if isErr && !bypassCachedErr {
+				return "", fmt.Errorf("whois: query for whois server (%s) failed (cached): %s", server, msg)
+			}
+			if !isErr {
+				return cached, nil
+			}
+		}
+
+		defer func() {
+			if err != nil {
+				c.cache.Set(key, encodeCacheErr(err), c.cacheNegativeTTL)
+				return
+			}
+			c.cache.Set(key, result, c.cacheTTL)
+		}()
+	}
+
 	c.elapsed = 0
 	// start := time.Now()
 	if server == "whois.arin.net" {
@@ -210,24 +268,24 @@ func (c *Client) rawQuery(domain, server, port string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
 	defer cancel()
 
-	// conn, err := c.dialer.DialContext(ctx, "tcp", net.JoinHostPort(server, port))
-	conn, err := dialContext(ctx, c.dialer, "tcp", net.JoinHostPort(server, port))
+	conn, err := c.dialContext(ctx, "tcp", net.JoinHostPort(server, port))
 	if err != nil {
 		return "", fmt.Errorf("whois: connect to whois server (%s) failed: %w", server, err)
 	}
 
 	defer conn.Close()
-	// c.elapsed = time.Since(start)
 
-	// _ = conn.SetWriteDeadline(time.Now().Add(c.timeout - c.elapsed))
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetWriteDeadline(deadline)
+	}
 	_, err = conn.Write([]byte(domain + "\r\n"))
 	if err != nil {
 		return "", fmt.Errorf("whois: send to whois server (%s) failed: %w", server, err)
 	}
 
-	// c.elapsed = time.Since(start)
-
-	// _ = conn.SetReadDeadline(time.Now().Add(c.timeout - c.elapsed))
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetReadDeadline(deadline)
+	}
 	buffer, err := io.ReadAll(conn)
 	if err != nil {
 		return "", fmt.Errorf("whois: read from whois server (%s) failed: %w", server, err)
@@ -272,37 +330,68 @@ func getServer(data string) (string, string) {
 	return "", ""
 }
 
-// dialContext 尝试使用给定的代理Dialer和context来建立连接
-func dialContext(ctx context.Context, dialer proxy.Dialer, network, addr string) (net.Conn, error) {
-	// 注意：这里仅为示例，实际上golang.org/x/net/proxy包的Dialer可能不直接支持context。
-	// 如果你的代理Dialer支持DialContext，直接使用它。
-	// 否则，你需要根据具体的Dialer实现调整此函数。
-	ch := make(chan net.Conn, 1)
-	var dialErr error
+// DialContextFunc dials addr the same way net.Dialer.DialContext does,
+// returning promptly and closing any partially-established connection
+// if ctx is canceled before the dial completes.
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// SetDialContext overrides how Client dials whois servers. Most
+// callers should use SetDialer instead; this is for dialers that need
+// full control over context handling beyond what SetDialer provides.
+func (c *Client) SetDialContext(dial DialContextFunc) *Client {
+	c.dialContextFunc = dial
+	return c
+}
+
+// dialContext establishes a connection honoring ctx's deadline and
+// cancellation. If an explicit DialContextFunc was set via
+// SetDialContext, it's used directly. Otherwise, if the configured
+// proxy.Dialer implements proxy.ContextDialer, its DialContext is used
+// directly; if not, dialer.Dial runs in a goroutine and dialContext
+// returns ctx.Err() as soon as ctx is done. That goroutine cannot
+// interrupt Dial: it lives until Dial returns, and a connection that
+// arrives after ctx is done is then closed rather than leaked.
+func (c *Client) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if c.dialContextFunc != nil {
+		return c.dialContextFunc(ctx, network, addr)
+	}
+
+	if cd, ok := c.dialer.(proxy.ContextDialer); ok {
+		return cd.DialContext(ctx, network, addr)
+	}
+
+	type dialResult struct {
+		conn net.Conn
+		err  error
+	}
+
+	resultCh := make(chan dialResult, 1)
 	go func() {
-		conn, err := dialer.Dial(network, addr)
-		if err != nil {
-			dialErr = err
-			ch <- nil
-			return
-		}
-		ch <- conn
+		conn, err := c.dialer.Dial(network, addr)
+		resultCh <- dialResult{conn, err}
 	}()
 
 	select {
-	case conn := <-ch:
-		return conn, dialErr
+	case res := <-resultCh:
+		return res.conn, res.err
 	case <-ctx.Done():
+		go func() {
+			if res := <-resultCh; res.conn != nil {
+				res.conn.Close()
+			}
+		}()
 		return nil, ctx.Err()
 	}
 }
 
+// InitWhois loads a whois-server config file into the package-wide
+// server map, on top of the compiled-in fallback snapshot that's
+// already in effect. It's optional - Clients work without it - and
+// only the first call takes effect.
 // sync.Once的作用是确保在多线程环境下一个操作只被执行一次
 func InitWhois(configFile string) {
 	onceWhois.Do(func() {
-		serverMapInstance = NewServerMap()
-		err := serverMapInstance.LoadFromFile(configFile)
-		if err != nil {
+		if err := defaultServerMap().LoadFromFile(configFile); err != nil {
 			// Handle error, e.g., log it, panic, etc.
 			panic(err)
 		}